@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// envBatchWorkers - переменная окружения для размера пула воркеров батч-анализа
+const envBatchWorkers = "ANALYZE_BATCH_WORKERS"
+
+// maxBatchLineBytes - максимальный размер одной строки NDJSON во входном батче
+const maxBatchLineBytes = 10 * 1024 * 1024 // 10 МБ
+
+// batchRequestLine - одна строка входного NDJSON-батча
+type batchRequestLine struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Delimiter string `json:"delimiter"`
+}
+
+// batchJob - задача для воркера: распарсенная строка батча и ее порядковый номер
+type batchJob struct {
+	seq      int
+	line     batchRequestLine
+	parseErr error
+}
+
+// batchResult - одна строка результирующего NDJSON-потока
+type batchResult struct {
+	Seq           int              `json:"-"`
+	ID            string           `json:"id"`
+	Error         string           `json:"error,omitempty"`
+	CharCount     int              `json:"charCount,omitempty"`
+	WordCount     int              `json:"wordCount,omitempty"`
+	SentenceCount int              `json:"sentenceCount,omitempty"`
+	ReadingTime   string           `json:"readingTime,omitempty"`
+	FrequentWords []map[string]int `json:"frequentWords,omitempty"`
+}
+
+// batchWorkerCount определяет размер пула воркеров: берется из envBatchWorkers,
+// если он задан и валиден, иначе используется runtime.NumCPU()
+func batchWorkerCount() int {
+	if v := os.Getenv(envBatchWorkers); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return runtime.NumCPU()
+}
+
+// AnalyzeBatchHandler обрабатывает потоковый анализ большого корпуса текстов.
+// Тело запроса - NDJSON (по одному объекту {"id","text","delimiter"} на строку),
+// ответ - тоже NDJSON, результаты отдаются клиенту по мере готовности, без
+// буферизации всего батча в памяти. Порядок параллельных воркеров не гарантирован;
+// чтобы сохранить порядок входа, передайте ?ordered=true.
+func (h *HTTPHandler) AnalyzeBatchHandler(w http.ResponseWriter, r *http.Request) {
+	// CORS
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		sendJSONError(w, http.StatusMethodNotAllowed, "Метод не поддерживается")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendJSONError(w, http.StatusInternalServerError, "Потоковая передача не поддерживается")
+		return
+	}
+
+	ordered := r.URL.Query().Get("ordered") == "true"
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	jobs := make(chan batchJob)
+	results := make(chan batchResult)
+
+	numWorkers := batchWorkerCount()
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- h.analyzeBatchLine(r.Context(), job)
+			}
+		}()
+	}
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		writeBatchResults(w, flusher, results, ordered)
+	}()
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBatchLineBytes)
+
+	seq := 0
+	for scanner.Scan() {
+		rawLine := scanner.Bytes()
+		if len(bytes.TrimSpace(rawLine)) == 0 {
+			continue
+		}
+
+		var parsed batchRequestLine
+		job := batchJob{seq: seq}
+		if err := json.Unmarshal(rawLine, &parsed); err != nil {
+			job.parseErr = err
+		} else {
+			job.line = parsed
+		}
+		jobs <- job
+		seq++
+	}
+	close(jobs)
+
+	wg.Wait()
+	close(results)
+	<-writerDone
+
+	if err := scanner.Err(); err != nil {
+		log.Printf("ошибка чтения батча: %v", err)
+	}
+}
+
+// analyzeBatchLine анализирует одну строку батча; ошибки парсинга или анализа
+// не прерывают весь батч, а отражаются в поле Error конкретного результата
+func (h *HTTPHandler) analyzeBatchLine(ctx context.Context, job batchJob) batchResult {
+	if job.parseErr != nil {
+		return batchResult{Seq: job.seq, Error: "невалидный JSON: " + job.parseErr.Error()}
+	}
+
+	analysis := h.analyzer.Analyze(ctx, job.line.Text, job.line.Delimiter, ReadingSpeedOptions{}, nil, "")
+
+	return batchResult{
+		Seq:           job.seq,
+		ID:            job.line.ID,
+		CharCount:     analysis.CharCount,
+		WordCount:     analysis.WordCount,
+		SentenceCount: analysis.SentenceCount,
+		ReadingTime:   analysis.ReadingTime,
+		FrequentWords: convertToMap(analysis.FrequentWords),
+	}
+}
+
+// writeBatchResults - единственная горутина-писатель: сериализует результаты в NDJSON
+// и сбрасывает буфер после каждой строки, чтобы клиент видел прогресс по мере готовности.
+// При ordered=true результаты буферизуются по seq и отдаются строго по порядку входа.
+func writeBatchResults(w http.ResponseWriter, flusher http.Flusher, results <-chan batchResult, ordered bool) {
+	encoder := json.NewEncoder(w)
+
+	if !ordered {
+		for res := range results {
+			if err := encoder.Encode(res); err != nil {
+				log.Printf("ошибка записи результата батча: %v", err)
+				continue
+			}
+			flusher.Flush()
+		}
+		return
+	}
+
+	pending := make(map[int]batchResult)
+	next := 0
+	for res := range results {
+		pending[res.Seq] = res
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			if err := encoder.Encode(r); err != nil {
+				log.Printf("ошибка записи результата батча: %v", err)
+			}
+			flusher.Flush()
+			delete(pending, next)
+			next++
+		}
+	}
+}
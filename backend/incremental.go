@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrDocumentTooLarge возвращается IncrementalAnalyzer.Update, когда текст превышает maxDocRunes
+var ErrDocumentTooLarge = errors.New("документ превышает допустимый размер")
+
+// IncrementalAnalyzer поддерживает между сообщениями карту частот слов, количество
+// рун и количество предложений, чтобы каждое Update пересчитывало не весь документ,
+// а только изменившуюся (по общему префиксу/суффиксу с предыдущей версией) середину.
+// Используется websocket-обработчиком живого набора текста (см. ws.go).
+type IncrementalAnalyzer struct {
+	mu          sync.Mutex
+	delimiter   string
+	maxDocRunes int
+
+	text          []rune
+	wordCount     int
+	sentenceCount int
+	freq          map[string]int // очищенное слово -> количество вхождений
+}
+
+// NewIncrementalAnalyzer создает инкрементальный анализатор для одного websocket-соединения
+func NewIncrementalAnalyzer(delimiter string, maxDocRunes int) *IncrementalAnalyzer {
+	return &IncrementalAnalyzer{
+		delimiter:   delimiter,
+		maxDocRunes: maxDocRunes,
+		freq:        make(map[string]int),
+	}
+}
+
+// Update пересчитывает анализ для нового состояния документа, используя дельту
+// относительно предыдущего вызова вместо полной ретокенизации
+func (ia *IncrementalAnalyzer) Update(newText string) (TextAnalysis, error) {
+	newRunes := []rune(newText)
+	if len(newRunes) > ia.maxDocRunes {
+		return TextAnalysis{}, ErrDocumentTooLarge
+	}
+
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	oldRunes := ia.text
+
+	prefix := commonPrefixLen(oldRunes, newRunes)
+	suffix := commonSuffixLen(oldRunes[prefix:], newRunes[prefix:])
+
+	oldStart, oldEnd := wordAlignedBounds(oldRunes, prefix, len(oldRunes)-suffix)
+	newStart, newEnd := wordAlignedBounds(newRunes, prefix, len(newRunes)-suffix)
+
+	oldMiddle := string(oldRunes[oldStart:oldEnd])
+	newMiddle := string(newRunes[newStart:newEnd])
+
+	oldWords := extractWords(oldMiddle)
+	newWords := extractWords(newMiddle)
+
+	for _, w := range oldWords {
+		ia.removeWord(w)
+	}
+	for _, w := range newWords {
+		ia.addWord(w)
+	}
+	ia.wordCount += len(newWords) - len(oldWords)
+
+	// Границы предложений пересчитываем только если затронутый фрагмент мог их
+	// изменить - иначе число предложений гарантированно не поменялось
+	if containsSentenceBoundary(oldMiddle, ia.delimiter) || containsSentenceBoundary(newMiddle, ia.delimiter) {
+		ia.sentenceCount = len(splitSentences(newText, ia.delimiter))
+	}
+
+	ia.text = newRunes
+
+	frequentWords := topFrequentWords(ia.freq, 2)
+	readingTime := calculateReadingTimeSimple(newText, ia.wordCount, len(newRunes), defaultReadingSpeedWPM, defaultCJKSpeedCPM)
+
+	return TextAnalysis{
+		CharCount:     len(newRunes),
+		WordCount:     ia.wordCount,
+		SentenceCount: ia.sentenceCount,
+		FrequentWords: frequentWords,
+		ReadingTime:   readingTime,
+	}, nil
+}
+
+func (ia *IncrementalAnalyzer) removeWord(raw string) {
+	cw := cleanWord(raw)
+	if cw == "" {
+		return
+	}
+	if ia.freq[cw] <= 1 {
+		delete(ia.freq, cw)
+	} else {
+		ia.freq[cw]--
+	}
+}
+
+func (ia *IncrementalAnalyzer) addWord(raw string) {
+	cw := cleanWord(raw)
+	if cw == "" {
+		return
+	}
+	ia.freq[cw]++
+}
+
+// commonPrefixLen возвращает длину общего префикса a и b
+func commonPrefixLen(a, b []rune) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	i := 0
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// commonSuffixLen возвращает длину общего суффикса a и b
+func commonSuffixLen(a, b []rune) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	i := 0
+	for i < n && a[len(a)-1-i] == b[len(b)-1-i] {
+		i++
+	}
+	return i
+}
+
+// wordAlignedBounds расширяет [start, end) до границ пробельных символов,
+// чтобы диапазон не разрезал слово пополам
+func wordAlignedBounds(runes []rune, start, end int) (int, int) {
+	if end < start {
+		end = start
+	}
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	return start, end
+}
+
+// containsSentenceBoundary сообщает, содержит ли фрагмент символы, способные
+// изменить разбиение на предложения при текущем delimiter
+func containsSentenceBoundary(fragment, delimiter string) bool {
+	if delimiter == "" {
+		return strings.ContainsAny(fragment, ".!?")
+	}
+	return strings.Contains(fragment, delimiter)
+}
+
+// topFrequentWords - как getFrequentWords, но строится напрямую из готовой карты частот
+func topFrequentWords(freq map[string]int, topN int) []WordFrequency {
+	frequencies := make([]WordFrequency, 0, len(freq))
+	for word, count := range freq {
+		frequencies = append(frequencies, WordFrequency{Word: word, Count: count})
+	}
+
+	sort.Slice(frequencies, func(i, j int) bool {
+		if frequencies[i].Count == frequencies[j].Count {
+			return frequencies[i].Word < frequencies[j].Word
+		}
+		return frequencies[i].Count > frequencies[j].Count
+	})
+
+	if topN > len(frequencies) {
+		topN = len(frequencies)
+	}
+	return frequencies[:topN]
+}
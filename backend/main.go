@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 )
 
 // TextAnalysis представляет результат анализа текста
@@ -17,32 +19,94 @@ type TextAnalysis struct {
 	SentenceCount int
 	FrequentWords []WordFrequency
 	ReadingTime   string
+	// Metrics - результаты опциональных Metric-плагинов, запрошенных явно
+	// через поле "metrics" в запросе. Нет запрошенных метрик - nil.
+	Metrics map[string]any
 }
 
-// WordFrequency представляет слово и его частоту
+// WordFrequency представляет слово и его частоту. Translation/PartOfSpeech/Phonetic
+// заполняются только опционально, при включенном обогащении переводом (см. translate.go)
 type WordFrequency struct {
-	Word  string
-	Count int
+	Word         string
+	Count        int
+	Translation  *string // nil, если перевод не запрашивался или не удался для этого слова
+	PartOfSpeech string
+	Phonetic     string
 }
 
 // ==================== Use Case Layer ====================
 
+// Скорости чтения по умолчанию, слов/мин для латиницы и кириллицы,
+// символов/мин для CJK (китайский, японский, корейский)
+const (
+	defaultReadingSpeedWPM = 200.0
+	defaultCJKSpeedCPM     = 500.0
+)
+
+// ReadingSpeedOptions позволяет переопределить скорости чтения для конкретного запроса.
+// Нулевое значение поля означает "использовать настройку анализатора по умолчанию".
+type ReadingSpeedOptions struct {
+	DefaultSpeed float64
+	CJKSpeed     float64
+}
+
 // TextAnalyzer - интерфейс для анализа текста
 type TextAnalyzer interface {
-	Analyze(text string, delimiter string) TextAnalysis
+	Analyze(ctx context.Context, text string, delimiter string, speeds ReadingSpeedOptions, metricNames []string, translateLang string) TextAnalysis
 }
 
 // DefaultAnalyzer - реализация анализатора текста
-type DefaultAnalyzer struct{}
+type DefaultAnalyzer struct {
+	defaultReadingSpeed float64    // слов/мин для латиницы/кириллицы
+	cjkReadingSpeed     float64    // символов/мин для CJK
+	translator          Translator // используется только если в запросе указан translateLang
+}
 
-// Analyze выполняет анализ текста
-func (a *DefaultAnalyzer) Analyze(text string, delimiter string) TextAnalysis {
-	charCount := countCharacters(text)
-	words := extractWords(text)
-	wordCount := len(words)
-	sentenceCount := countSentences(text, delimiter)
-	frequentWords := getFrequentWords(words, 2)
-	readingTime := calculateReadingTimeSimple(wordCount, charCount)
+// NewDefaultAnalyzer создает анализатор со скоростями чтения по умолчанию
+func NewDefaultAnalyzer() *DefaultAnalyzer {
+	return &DefaultAnalyzer{
+		defaultReadingSpeed: defaultReadingSpeedWPM,
+		cjkReadingSpeed:     defaultCJKSpeedCPM,
+		translator:          newDictionaryTranslator(),
+	}
+}
+
+// Analyze выполняет анализ текста. Базовые счетчики (символы, слова, предложения,
+// частотные слова, время чтения) считаются всегда; metricNames - это опциональный
+// список Metric-плагинов (см. metrics.go), которые дополнительно прогоняются над уже
+// токенизированным текстом; translateLang - опциональный код языка ("en" и т.п.),
+// включающий обогащение FrequentWords переводом (см. translate.go).
+func (a *DefaultAnalyzer) Analyze(ctx context.Context, text string, delimiter string, speeds ReadingSpeedOptions, metricNames []string, translateLang string) TextAnalysis {
+	tokens := newTokenizedText(text, delimiter)
+
+	charCount := len(tokens.Runes)
+	wordCount := len(tokens.Words)
+	sentenceCount := len(tokens.Sentences)
+	frequentWords := getFrequentWords(tokens.Words, 2)
+
+	if translateLang != "" && a.translator != nil {
+		frequentWords = enrichWithTranslations(ctx, a.translator, frequentWords, translateLang)
+	}
+
+	defaultSpeed := speeds.DefaultSpeed
+	if defaultSpeed <= 0 {
+		defaultSpeed = a.defaultReadingSpeed
+	}
+	cjkSpeed := speeds.CJKSpeed
+	if cjkSpeed <= 0 {
+		cjkSpeed = a.cjkReadingSpeed
+	}
+	readingTime := calculateReadingTimeSimple(text, wordCount, charCount, defaultSpeed, cjkSpeed)
+
+	var metrics map[string]any
+	if len(metricNames) > 0 {
+		metrics = make(map[string]any, len(metricNames))
+		for _, name := range metricNames {
+			if m, ok := lookupMetric(name); ok {
+				metrics[name] = m.Compute(tokens)
+			}
+		}
+	}
 
 	return TextAnalysis{
 		CharCount:     charCount,
@@ -50,6 +114,7 @@ func (a *DefaultAnalyzer) Analyze(text string, delimiter string) TextAnalysis {
 		SentenceCount: sentenceCount,
 		FrequentWords: frequentWords,
 		ReadingTime:   readingTime,
+		Metrics:       metrics,
 	}
 }
 
@@ -83,8 +148,12 @@ func (h *HTTPHandler) AnalyzeTextHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	var request struct {
-		Text      string `json:"text"`
-		Delimiter string `json:"delimiter"`
+		Text                string   `json:"text"`
+		Delimiter           string   `json:"delimiter"`
+		DefaultReadingSpeed float64  `json:"defaultReadingSpeed"`
+		CJKReadingSpeed     float64  `json:"cjkReadingSpeed"`
+		Metrics             []string `json:"metrics"`
+		Translate           string   `json:"translate"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -92,16 +161,39 @@ func (h *HTTPHandler) AnalyzeTextHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if unknown := unknownMetrics(request.Metrics); len(unknown) > 0 {
+		sendJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":          "неизвестные метрики",
+			"unknownMetrics": unknown,
+		})
+		return
+	}
+
 	// Используем use case
-	analysis := h.analyzer.Analyze(request.Text, request.Delimiter)
+	analysis := h.analyzer.Analyze(r.Context(), request.Text, request.Delimiter, ReadingSpeedOptions{
+		DefaultSpeed: request.DefaultReadingSpeed,
+		CJKSpeed:     request.CJKReadingSpeed,
+	}, request.Metrics, request.Translate)
+
+	// Преобразуем в DTO для HTTP. Обычный {word: count} формат сохраняется, пока
+	// перевод не запрошен; при "translate" каждое слово становится объектом,
+	// чтобы вместить перевод/часть речи/транскрипцию.
+	var frequentWords interface{}
+	if request.Translate != "" {
+		frequentWords = convertToEnrichedWords(analysis.FrequentWords)
+	} else {
+		frequentWords = convertToMap(analysis.FrequentWords)
+	}
 
-	// Преобразуем в DTO для HTTP
 	response := map[string]interface{}{
 		"charCount":     analysis.CharCount,
 		"wordCount":     analysis.WordCount,
 		"sentenceCount": analysis.SentenceCount,
 		"readingTime":   analysis.ReadingTime,
-		"frequentWords": convertToMap(analysis.FrequentWords),
+		"frequentWords": frequentWords,
+	}
+	if analysis.Metrics != nil {
+		response["metrics"] = analysis.Metrics
 	}
 
 	sendJSON(w, http.StatusOK, response)
@@ -109,42 +201,30 @@ func (h *HTTPHandler) AnalyzeTextHandler(w http.ResponseWriter, r *http.Request)
 
 // ==================== Business Logic (Pure Functions) ====================
 
-// countCharacters подсчитывает количество символов
-func countCharacters(text string) int {
-	return len([]rune(text))
-}
-
 // extractWords извлекает слова из текста
 func extractWords(text string) []string {
 	return strings.Fields(text)
 }
 
-// countSentences подсчитывает количество предложений
-// Если delimiter пустой, используется стандартная логика [.!?]+
-// Если delimiter указан, используется указанный символ(ы) для разделения
-func countSentences(text string, delimiter string) int {
+// splitSentences разбивает текст на предложения и отбрасывает пустые фрагменты.
+// Если delimiter пустой, используется стандартная логика [.!?]+, иначе -
+// разбиение по указанному разделителю.
+func splitSentences(text string, delimiter string) []string {
+	var parts []string
 	if delimiter == "" {
-		// Стандартная логика: используем . ! ?
 		sentenceRegex := regexp.MustCompile(`[.!?]+`)
-		sentences := sentenceRegex.Split(text, -1)
-		count := 0
-		for _, s := range sentences {
-			if strings.TrimSpace(s) != "" {
-				count++
-			}
-		}
-		return count
+		parts = sentenceRegex.Split(text, -1)
+	} else {
+		parts = strings.Split(text, delimiter)
 	}
-	
-	// Пользовательский разделитель: просто разбиваем по указанному символу
-	parts := strings.Split(text, delimiter)
-	count := 0
-	for _, part := range parts {
-		if strings.TrimSpace(part) != "" {
-			count++
+
+	sentences := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			sentences = append(sentences, trimmed)
 		}
 	}
-	return count
+	return sentences
 }
 
 // getFrequentWords возвращает самые частые слова
@@ -187,8 +267,117 @@ func cleanWord(word string) string {
 	return strings.Trim(clean, trimChars)
 }
 
-// calculateReadingTimeSimple простой, но улучшенный расчёт
-func calculateReadingTimeSimple(wordCount, charCount int) string {
+// cjkScriptThreshold - доля CJK-символов среди непробельных, начиная с которой
+// текст считается преимущественно китайским/японским/корейским
+const cjkScriptThreshold = 0.3
+
+// cjkSampleRunes - сколько рун сканировать для определения письменности
+// (чтобы не проходить по всему тексту на больших входных данных)
+const cjkSampleRunes = 250
+
+// dominantScript - письменность, преобладающая во входном тексте
+type dominantScript int
+
+const (
+	scriptOther dominantScript = iota // кириллица и всё остальное - используем русское склонение
+	scriptLatin                       // латиница - используем английскую плюрализацию
+	scriptCJK                         // китайский/японский/корейский - считаем по символам, а не по словам
+)
+
+// isCJKRune сообщает, принадлежит ли руна диапазонам CJK Unified Ideographs,
+// хираганы, катаканы или хангыля
+func isCJKRune(r rune) bool {
+	switch {
+	case r >= 0x4E00 && r <= 0x9FFF: // CJK Unified Ideographs
+		return true
+	case r >= 0x3040 && r <= 0x309F: // Hiragana
+		return true
+	case r >= 0x30A0 && r <= 0x30FF: // Katakana
+		return true
+	case r >= 0xAC00 && r <= 0xD7AF: // Hangul
+		return true
+	default:
+		return false
+	}
+}
+
+func isLatinRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// detectDominantScript сканирует первые cjkSampleRunes рун текста и определяет,
+// какая письменность в нем преобладает
+func detectDominantScript(text string) dominantScript {
+	runes := []rune(text)
+	if len(runes) > cjkSampleRunes {
+		runes = runes[:cjkSampleRunes]
+	}
+
+	var cjkCount, latinCount, nonSpaceCount int
+	for _, r := range runes {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		nonSpaceCount++
+		switch {
+		case isCJKRune(r):
+			cjkCount++
+		case isLatinRune(r):
+			latinCount++
+		}
+	}
+
+	if nonSpaceCount == 0 {
+		return scriptOther
+	}
+	if float64(cjkCount)/float64(nonSpaceCount) > cjkScriptThreshold {
+		return scriptCJK
+	}
+	if latinCount*2 > nonSpaceCount {
+		return scriptLatin
+	}
+	return scriptOther
+}
+
+// countCJKRunes подсчитывает количество CJK-символов во всем тексте
+func countCJKRunes(text string) int {
+	count := 0
+	for _, r := range text {
+		if isCJKRune(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// countNonCJKWords подсчитывает "слова" (strings.Fields) текста, не содержащие ни одной
+// CJK-руны - то есть настоящие латинские/кириллические вкрапления в смешанном тексте,
+// которые не были уже учтены через countCJKRunes
+func countNonCJKWords(text string) int {
+	count := 0
+	for _, w := range strings.Fields(text) {
+		if !strings.ContainsFunc(w, isCJKRune) {
+			count++
+		}
+	}
+	return count
+}
+
+// calculateReadingTimeSimple рассчитывает время чтения с учетом письменности текста.
+// Для CJK-текста "слова" в понимании strings.Fields не имеют смысла, поэтому скорость
+// считается по символам (cjkSpeed символов/мин); оставшиеся "латинские" слова (если текст
+// смешанный) добавляются по defaultSpeed слов/мин. Для латиницы и кириллицы используется
+// прежняя логика на основе средней длины слова.
+func calculateReadingTimeSimple(text string, wordCount, charCount int, defaultSpeed, cjkSpeed float64) string {
+	script := detectDominantScript(text)
+
+	if script == scriptCJK {
+		cjkRunes := countCJKRunes(text)
+		latinWordCount := countNonCJKWords(text)
+		minutes := float64(cjkRunes)/cjkSpeed + float64(latinWordCount)/defaultSpeed
+		return formatReadingMinutes(minutes, scriptOther)
+	}
+
 	if wordCount == 0 {
 		return "0 минут"
 	}
@@ -196,15 +385,13 @@ func calculateReadingTimeSimple(wordCount, charCount int) string {
 	// Рассчитываем среднюю длину слова
 	averageWordLength := float64(charCount) / float64(wordCount)
 
-	// Базовая скорость чтения
-	baseSpeed := 200.0 // слов в минуту
-
 	// Корректируем скорость в зависимости от средней длины слова
 	// Формула: чем длиннее слова, тем медленнее читаем
-	// Эмпирическая формула: speed = 200 * (5 / averageWordLength)
+	// Эмпирическая формула: speed = defaultSpeed * (5 / averageWordLength)
 	// Где 5 - средняя длина слова в русском языке
+	adjustedSpeed := defaultSpeed
 	if averageWordLength > 0 {
-		adjustedSpeed := baseSpeed * (5.0 / averageWordLength)
+		adjustedSpeed = defaultSpeed * (5.0 / averageWordLength)
 		// Ограничиваем разумными пределами
 		if adjustedSpeed < 100 {
 			adjustedSpeed = 100
@@ -212,12 +399,26 @@ func calculateReadingTimeSimple(wordCount, charCount int) string {
 		if adjustedSpeed > 300 {
 			adjustedSpeed = 300
 		}
-		baseSpeed = adjustedSpeed
 	}
 
-	minutes := float64(wordCount) / baseSpeed
+	minutes := float64(wordCount) / adjustedSpeed
+
+	return formatReadingMinutes(minutes, script)
+}
+
+// formatReadingMinutes форматирует количество минут в читаемую строку: с русским
+// склонением по умолчанию, либо с английской плюрализацией для латиницы
+func formatReadingMinutes(minutes float64, script dominantScript) string {
+	if script == scriptLatin {
+		if minutes < 1 {
+			return "less than a minute"
+		}
+		if int(minutes) == 1 {
+			return "1 minute"
+		}
+		return fmt.Sprintf("%.0f minutes", minutes)
+	}
 
-	// Форматирование результата
 	if minutes < 1 {
 		return "меньше минуты"
 	}
@@ -251,6 +452,28 @@ func convertToMap(words []WordFrequency) []map[string]int {
 	return result
 }
 
+// convertToEnrichedWords преобразует WordFrequency в объекты, вмещающие перевод:
+// translation сериализуется как null, если перевод запрашивался, но не удался для
+// этого слова; partOfSpeech/phonetic опускаются, если пусты.
+func convertToEnrichedWords(words []WordFrequency) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(words))
+	for i, wf := range words {
+		entry := map[string]interface{}{
+			"word":        wf.Word,
+			"count":       wf.Count,
+			"translation": wf.Translation,
+		}
+		if wf.PartOfSpeech != "" {
+			entry["partOfSpeech"] = wf.PartOfSpeech
+		}
+		if wf.Phonetic != "" {
+			entry["phonetic"] = wf.Phonetic
+		}
+		result[i] = entry
+	}
+	return result
+}
+
 // sendJSON отправляет JSON ответ
 func sendJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -267,15 +490,19 @@ func sendJSONError(w http.ResponseWriter, status int, message string) {
 
 func main() {
 	// Инициализация зависимостей
-	analyzer := &DefaultAnalyzer{}
+	analyzer := NewDefaultAnalyzer()
 	handler := NewHTTPHandler(analyzer)
+	metrics := newTelemetry()
 
 	// Настройка маршрутов
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/analyze", handler.AnalyzeTextHandler)
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/analyze", instrumentMetrics(handler.AnalyzeTextHandler, metrics))
+	mux.HandleFunc("/api/analyze/batch", instrumentMetrics(handler.AnalyzeBatchHandler, metrics))
+	mux.HandleFunc("/api/analyze/ws", handler.AnalyzeWSHandler)
+	mux.HandleFunc("/health", instrumentMetrics(func(w http.ResponseWriter, r *http.Request) {
 		sendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
-	})
+	}, metrics))
+	mux.HandleFunc("/metrics", metrics.MetricsHandler)
 
 	// Настройка сервера
 	server := &http.Server{
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+// TestDetectDominantScript проверяет классификацию письменности на чисто
+// латинском, кириллическом, CJK и смешанном тексте
+func TestDetectDominantScript(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want dominantScript
+	}{
+		{"латиница", "the quick brown fox jumps over the lazy dog", scriptLatin},
+		{"кириллица", "съешь же ещё этих мягких французских булок", scriptOther},
+		{"китайский", "测试文本内容用于检测占主导地位的文字系统", scriptCJK},
+		{"смешанный с преобладанием CJK", "测试文本内容 word 测试文本内容 word 测试文本内容", scriptCJK},
+		{"пусто", "", scriptOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDominantScript(tt.text); got != tt.want {
+				t.Errorf("detectDominantScript(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCalculateReadingTimeSimple_MixedCJKDoesNotDoubleCountWords проверяет, что для
+// смешанного CJK/латинского текста в остаток по defaultSpeed попадают только настоящие
+// латинские слова, а не все строки, найденные strings.Fields (включая CJK-токены).
+func TestCalculateReadingTimeSimple_MixedCJKDoesNotDoubleCountWords(t *testing.T) {
+	const cjkToken = "测试文本内容" // 6 CJK-рун, единый пробельно-разделенный токен
+
+	var sb []byte
+	for i := 0; i < 500; i++ {
+		sb = append(sb, []byte(cjkToken+" word ")...)
+	}
+	text := string(sb)
+
+	tokens := newTokenizedText(text, "")
+	wordCount := len(tokens.Words)
+	charCount := len(tokens.Runes)
+
+	got := calculateReadingTimeSimple(text, wordCount, charCount, defaultReadingSpeedWPM, defaultCJKSpeedCPM)
+
+	cjkRunes := countCJKRunes(text)
+	latinWords := countNonCJKWords(text)
+	wantMinutes := float64(cjkRunes)/defaultCJKSpeedCPM + float64(latinWords)/defaultReadingSpeedWPM
+	want := formatReadingMinutes(wantMinutes, scriptOther)
+
+	if got != want {
+		t.Errorf("calculateReadingTimeSimple() = %q, want %q (latin words = %d, not wordCount = %d)", got, want, latinWords, wordCount)
+	}
+}
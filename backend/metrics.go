@@ -0,0 +1,221 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// TokenizedText - результат однократной токенизации текста, разделяемый
+// между всеми Metric-плагинами, чтобы не пересчитывать руны/слова/предложения
+// для каждой метрики заново.
+type TokenizedText struct {
+	Text       string
+	Delimiter  string
+	Runes      []rune
+	Words      []string // "сырые" слова, strings.Fields(text)
+	CleanWords []string // слова в нижнем регистре без пунктуации, пустые отброшены
+	Sentences  []string // непустые предложения без пробелов по краям
+}
+
+// newTokenizedText токенизирует текст один раз для последующего использования метриками
+func newTokenizedText(text string, delimiter string) *TokenizedText {
+	words := extractWords(text)
+
+	cleanWords := make([]string, 0, len(words))
+	for _, w := range words {
+		if cw := cleanWord(w); cw != "" {
+			cleanWords = append(cleanWords, cw)
+		}
+	}
+
+	return &TokenizedText{
+		Text:       text,
+		Delimiter:  delimiter,
+		Runes:      []rune(text),
+		Words:      words,
+		CleanWords: cleanWords,
+		Sentences:  splitSentences(text, delimiter),
+	}
+}
+
+// Metric - плагин статистики текста. Compute получает уже токенизированный
+// текст и возвращает значение метрики (число, строка и т.п.) для сериализации в JSON.
+type Metric interface {
+	Name() string
+	Compute(tokens *TokenizedText) any
+}
+
+var (
+	metricRegistryMu sync.RWMutex
+	metricRegistry   = map[string]Metric{}
+)
+
+// RegisterMetric регистрирует метрику под ее Name(). Вызывается обычно из init(),
+// в том числе сторонними пакетами, расширяющими набор встроенных метрик.
+func RegisterMetric(m Metric) {
+	metricRegistryMu.Lock()
+	defer metricRegistryMu.Unlock()
+	metricRegistry[m.Name()] = m
+}
+
+// lookupMetric ищет зарегистрированную метрику по имени
+func lookupMetric(name string) (Metric, bool) {
+	metricRegistryMu.RLock()
+	defer metricRegistryMu.RUnlock()
+	m, ok := metricRegistry[name]
+	return m, ok
+}
+
+// unknownMetrics возвращает те имена из names, для которых нет зарегистрированной метрики
+func unknownMetrics(names []string) []string {
+	var unknown []string
+	for _, name := range names {
+		if _, ok := lookupMetric(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+func init() {
+	RegisterMetric(charCountMetric{})
+	RegisterMetric(wordCountMetric{})
+	RegisterMetric(sentenceCountMetric{})
+	RegisterMetric(uniqueWordCountMetric{})
+	RegisterMetric(lexicalDiversityMetric{})
+	RegisterMetric(avgSentenceLengthMetric{})
+	RegisterMetric(avgWordLengthMetric{})
+	RegisterMetric(longestWordMetric{})
+	RegisterMetric(readabilityMetric{})
+}
+
+// ==================== Встроенные метрики ====================
+
+type charCountMetric struct{}
+
+func (charCountMetric) Name() string                 { return "charCount" }
+func (charCountMetric) Compute(t *TokenizedText) any { return len(t.Runes) }
+
+type wordCountMetric struct{}
+
+func (wordCountMetric) Name() string                { return "wordCount" }
+func (wordCountMetric) Compute(t *TokenizedText) any { return len(t.Words) }
+
+type sentenceCountMetric struct{}
+
+func (sentenceCountMetric) Name() string                { return "sentenceCount" }
+func (sentenceCountMetric) Compute(t *TokenizedText) any { return len(t.Sentences) }
+
+// uniqueWordCountMetric - количество уникальных (после приведения к нижнему
+// регистру и очистки от пунктуации) слов
+type uniqueWordCountMetric struct{}
+
+func (uniqueWordCountMetric) Name() string { return "unique" }
+
+func (uniqueWordCountMetric) Compute(t *TokenizedText) any {
+	return len(uniqueWordSet(t.CleanWords))
+}
+
+// lexicalDiversityMetric - отношение уникальных слов к общему числу слов
+type lexicalDiversityMetric struct{}
+
+func (lexicalDiversityMetric) Name() string { return "diversity" }
+
+func (lexicalDiversityMetric) Compute(t *TokenizedText) any {
+	if len(t.CleanWords) == 0 {
+		return 0.0
+	}
+	return float64(len(uniqueWordSet(t.CleanWords))) / float64(len(t.CleanWords))
+}
+
+func uniqueWordSet(words []string) map[string]struct{} {
+	seen := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		seen[w] = struct{}{}
+	}
+	return seen
+}
+
+// avgSentenceLengthMetric - среднее количество слов на предложение
+type avgSentenceLengthMetric struct{}
+
+func (avgSentenceLengthMetric) Name() string { return "avgSentenceLength" }
+
+func (avgSentenceLengthMetric) Compute(t *TokenizedText) any {
+	if len(t.Sentences) == 0 {
+		return 0.0
+	}
+	return float64(len(t.Words)) / float64(len(t.Sentences))
+}
+
+// avgWordLengthMetric - средняя длина слова в рунах
+type avgWordLengthMetric struct{}
+
+func (avgWordLengthMetric) Name() string { return "avgWordLength" }
+
+func (avgWordLengthMetric) Compute(t *TokenizedText) any {
+	if len(t.CleanWords) == 0 {
+		return 0.0
+	}
+	totalLen := 0
+	for _, w := range t.CleanWords {
+		totalLen += len([]rune(w))
+	}
+	return float64(totalLen) / float64(len(t.CleanWords))
+}
+
+// longestWordMetric - самое длинное слово текста
+type longestWordMetric struct{}
+
+func (longestWordMetric) Name() string { return "longestWord" }
+
+func (longestWordMetric) Compute(t *TokenizedText) any {
+	longest := ""
+	for _, w := range t.CleanWords {
+		if len([]rune(w)) > len([]rune(longest)) {
+			longest = w
+		}
+	}
+	return longest
+}
+
+// readabilityMetric - читаемость текста по формуле, аналогичной Flesch Reading Ease
+type readabilityMetric struct{}
+
+func (readabilityMetric) Name() string { return "readability" }
+
+func (readabilityMetric) Compute(t *TokenizedText) any {
+	if len(t.CleanWords) == 0 || len(t.Sentences) == 0 {
+		return 0.0
+	}
+
+	syllables := 0
+	for _, w := range t.CleanWords {
+		syllables += countSyllables(w)
+	}
+
+	wordsPerSentence := float64(len(t.Words)) / float64(len(t.Sentences))
+	syllablesPerWord := float64(syllables) / float64(len(t.CleanWords))
+
+	return 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+}
+
+// countSyllables - грубая эвристика подсчета слогов по группам гласных подряд,
+// покрывающая латиницу и кириллицу
+func countSyllables(word string) int {
+	const vowels = "aeiouyаеёиоуыэюя"
+
+	count := 0
+	prevIsVowel := false
+	for _, r := range strings.ToLower(word) {
+		isVowel := strings.ContainsRune(vowels, r)
+		if isVowel && !prevIsVowel {
+			count++
+		}
+		prevIsVowel = isVowel
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
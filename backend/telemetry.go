@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==================== Гистограммы и счетчики ====================
+
+// histogram - минимальная реализация кумулятивной гистограммы в духе client_golang,
+// без внешней зависимости на Prometheus SDK
+type histogram struct {
+	mu      sync.Mutex
+	buckets []float64 // верхние границы бакетов по возрастанию
+	counts  []uint64  // кумулятивное число наблюдений <= buckets[i]
+	sum     float64
+	total   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += v
+	h.total++
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+}
+
+func (h *histogram) writeTo(sb *strings.Builder, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.buckets {
+		fmt.Fprintf(sb, "%s_bucket{le=\"%s\"} %d\n", name, strconv.FormatFloat(b, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(sb, "%s_bucket{le=\"+Inf\"} %d\n", name, h.total)
+	fmt.Fprintf(sb, "%s_sum %g\n", name, h.sum)
+	fmt.Fprintf(sb, "%s_count %d\n", name, h.total)
+}
+
+// ==================== Сбор метрик ====================
+
+// telemetry накапливает метрики HTTP-запросов и анализа текста, отдаваемые
+// в Prometheus text exposition format на /metrics
+type telemetry struct {
+	mu            sync.Mutex
+	requestsTotal map[string]uint64 // "method|path|status" -> количество
+
+	requestDuration *histogram // секунды, по всем эндпоинтам
+	textLength      *histogram // длина текста в рунах на /api/analyze
+	sentenceCounts  *histogram // число предложений на /api/analyze
+
+	wordsPerSecond     float64
+	textLengthSum      float64
+	textLengthRequests uint64
+}
+
+func newTelemetry() *telemetry {
+	return &telemetry{
+		requestsTotal:   make(map[string]uint64),
+		requestDuration: newHistogram([]float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5}),
+		textLength:      newHistogram([]float64{100, 1000, 10000, 100000, 1000000}),
+		sentenceCounts:  newHistogram([]float64{1, 5, 10, 50, 100, 500}),
+	}
+}
+
+func (t *telemetry) observeRequest(method, path string, status int, duration time.Duration) {
+	t.mu.Lock()
+	key := fmt.Sprintf("%s|%s|%d", method, path, status)
+	t.requestsTotal[key]++
+	t.mu.Unlock()
+
+	t.requestDuration.observe(duration.Seconds())
+}
+
+// observeAnalysis фиксирует гейджи/гистограммы, специфичные для анализа текста
+func (t *telemetry) observeAnalysis(charCount, wordCount, sentenceCount int, duration time.Duration) {
+	t.textLength.observe(float64(charCount))
+	t.sentenceCounts.observe(float64(sentenceCount))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.textLengthSum += float64(charCount)
+	t.textLengthRequests++
+	if duration > 0 {
+		t.wordsPerSecond = float64(wordCount) / duration.Seconds()
+	}
+}
+
+func (t *telemetry) avgTextLength() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.textLengthRequests == 0 {
+		return 0
+	}
+	return t.textLengthSum / float64(t.textLengthRequests)
+}
+
+// renderPrometheus сериализует накопленные метрики в Prometheus text exposition format
+func (t *telemetry) renderPrometheus() string {
+	var sb strings.Builder
+
+	t.mu.Lock()
+	keys := make([]string, 0, len(t.requestsTotal))
+	for k := range t.requestsTotal {
+		keys = append(keys, k)
+	}
+	counts := make(map[string]uint64, len(t.requestsTotal))
+	for k, v := range t.requestsTotal {
+		counts[k] = v
+	}
+	t.mu.Unlock()
+	sort.Strings(keys)
+
+	sb.WriteString("# HELP textreader_http_requests_total Общее количество HTTP-запросов\n")
+	sb.WriteString("# TYPE textreader_http_requests_total counter\n")
+	for _, k := range keys {
+		parts := strings.SplitN(k, "|", 3)
+		fmt.Fprintf(&sb, "textreader_http_requests_total{method=%q,path=%q,status=%q} %d\n", parts[0], parts[1], parts[2], counts[k])
+	}
+
+	sb.WriteString("# HELP textreader_http_request_duration_seconds Длительность обработки HTTP-запроса\n")
+	sb.WriteString("# TYPE textreader_http_request_duration_seconds histogram\n")
+	t.requestDuration.writeTo(&sb, "textreader_http_request_duration_seconds")
+
+	sb.WriteString("# HELP textreader_analyze_text_length_runes Длина анализируемого текста в рунах\n")
+	sb.WriteString("# TYPE textreader_analyze_text_length_runes histogram\n")
+	t.textLength.writeTo(&sb, "textreader_analyze_text_length_runes")
+
+	sb.WriteString("# HELP textreader_analyze_sentence_count Количество предложений в анализируемом тексте\n")
+	sb.WriteString("# TYPE textreader_analyze_sentence_count histogram\n")
+	t.sentenceCounts.writeTo(&sb, "textreader_analyze_sentence_count")
+
+	sb.WriteString("# HELP textreader_analyze_words_per_second Слов в секунду на последнем анализе /api/analyze\n")
+	sb.WriteString("# TYPE textreader_analyze_words_per_second gauge\n")
+	fmt.Fprintf(&sb, "textreader_analyze_words_per_second %g\n", t.wordsPerSecond)
+
+	sb.WriteString("# HELP textreader_analyze_avg_text_length Средняя длина анализируемого текста в рунах\n")
+	sb.WriteString("# TYPE textreader_analyze_avg_text_length gauge\n")
+	fmt.Fprintf(&sb, "textreader_analyze_avg_text_length %g\n", t.avgTextLength())
+
+	return sb.String()
+}
+
+// MetricsHandler отдает накопленные метрики в Prometheus text format на /metrics
+func (t *telemetry) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(t.renderPrometheus()))
+}
+
+// ==================== Middleware ====================
+
+// statusRecorder оборачивает http.ResponseWriter, запоминая итоговый статус и число
+// записанных байт. sendJSON сначала вызывает WriteHeader, затем Encode - без этой
+// обертки middleware не увидит ни тот, ни другой результат.
+type statusRecorder struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int
+	captureBody  bool
+	body         bytes.Buffer
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	if sr.captureBody {
+		sr.body.Write(b)
+	}
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytesWritten += n
+	return n, err
+}
+
+// Flush пробрасывает http.Flusher дальше, чтобы потоковые обработчики
+// (batch, websocket) продолжали работать под этой оберткой
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// accessLogEntry - структурированная (JSON) строка access-лога для /api/analyze
+type accessLogEntry struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Status     int    `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	CharCount  int    `json:"char_count"`
+	WordCount  int    `json:"word_count"`
+}
+
+// instrumentMetrics оборачивает обработчик записью метрик Prometheus и, для
+// /api/analyze, структурированным access-логом с размером и скоростью анализа
+func instrumentMetrics(next http.HandlerFunc, t *telemetry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		captureBody := r.URL.Path == "/api/analyze"
+		rec := &statusRecorder{ResponseWriter: w, captureBody: captureBody}
+
+		next(rec, r)
+
+		duration := time.Since(start)
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		t.observeRequest(r.Method, r.URL.Path, status, duration)
+
+		if !captureBody {
+			return
+		}
+
+		var parsed struct {
+			CharCount     int `json:"charCount"`
+			WordCount     int `json:"wordCount"`
+			SentenceCount int `json:"sentenceCount"`
+		}
+		_ = json.Unmarshal(rec.body.Bytes(), &parsed)
+
+		t.observeAnalysis(parsed.CharCount, parsed.WordCount, parsed.SentenceCount, duration)
+
+		entry := accessLogEntry{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Status:     status,
+			DurationMs: duration.Milliseconds(),
+			CharCount:  parsed.CharCount,
+			WordCount:  parsed.WordCount,
+		}
+		if line, err := json.Marshal(entry); err == nil {
+			log.Println(string(line))
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxConcurrentTranslations ограничивает число одновременных обращений к Translator
+const maxConcurrentTranslations = 4
+
+// translationCacheCapacity - емкость LRU-кэша переводов
+const translationCacheCapacity = 2048
+
+// Translator - интерфейс обогащения слова переводом, частью речи и транскрипцией
+type Translator interface {
+	Translate(ctx context.Context, word string, targetLang string) (translation, pos, phonetic string, err error)
+}
+
+// enrichWithTranslations обогащает копию frequentWords переводами, запрашивая их
+// параллельно (не более maxConcurrentTranslations одновременно). Ошибка перевода
+// отдельного слова не прерывает остальные - слово просто остается без перевода
+// (Translation == nil), см. конвертацию в convertToEnrichedWords.
+func enrichWithTranslations(ctx context.Context, translator Translator, words []WordFrequency, targetLang string) []WordFrequency {
+	enriched := make([]WordFrequency, len(words))
+	copy(enriched, words)
+
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentTranslations)
+
+	for i := range enriched {
+		i := i
+		g.Go(func() error {
+			translation, pos, phonetic, err := translateCached(ctx, translator, enriched[i].Word, targetLang)
+			if err != nil {
+				return nil
+			}
+			enriched[i].Translation = &translation
+			enriched[i].PartOfSpeech = pos
+			enriched[i].Phonetic = phonetic
+			return nil
+		})
+	}
+	_ = g.Wait() // отдельные ошибки уже поглощены выше, Wait тут нужен только чтобы дождаться всех
+
+	return enriched
+}
+
+// ==================== LRU-кэш переводов ====================
+
+type translationCacheEntry struct {
+	translation, pos, phonetic string
+}
+
+// translationCache - кэш переводов по ключу (слово, язык), общий для всех запросов
+var translationCache = newLRUCache(translationCacheCapacity)
+
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruNode struct {
+	key   string
+	value translationCacheEntry
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) (translationCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return translationCacheEntry{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruNode).value, true
+}
+
+func (c *lruCache) put(key string, value translationCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruNode).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&lruNode{key: key, value: value})
+	c.items[key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*lruNode).key)
+		}
+	}
+}
+
+func translationCacheKey(word, lang string) string {
+	return lang + "|" + word
+}
+
+// translateCached оборачивает Translator.Translate кэшем, чтобы один и тот же
+// (слово, язык) не запрашивался у внешнего API повторно
+func translateCached(ctx context.Context, translator Translator, word, lang string) (translation, pos, phonetic string, err error) {
+	key := translationCacheKey(word, lang)
+	if cached, ok := translationCache.get(key); ok {
+		return cached.translation, cached.pos, cached.phonetic, nil
+	}
+
+	translation, pos, phonetic, err = translator.Translate(ctx, word, lang)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	translationCache.put(key, translationCacheEntry{translation: translation, pos: pos, phonetic: phonetic})
+	return translation, pos, phonetic, nil
+}
+
+// ==================== Встроенный Translator ====================
+
+// envTranslateAPIEndpoint - переменная окружения с адресом словарного API.
+// Конкретный сторонний провайдер в коде не зашит, т.к. иначе сервис зависел бы
+// от доступности и формата ответа чужого эндпоинта без возможности его сменить.
+const envTranslateAPIEndpoint = "TRANSLATE_API_ENDPOINT"
+
+// dictionaryTranslator - Translator по умолчанию: POST {"word","lang"} на
+// словарное API в духе Youdao/Volcano и разбор ответа по пути
+// words[].pos_list[].explanations[].text
+type dictionaryTranslator struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+func newDictionaryTranslator() *dictionaryTranslator {
+	endpoint := os.Getenv(envTranslateAPIEndpoint)
+	if endpoint == "" {
+		log.Printf("перевод отключен: не задана переменная окружения %s - запросы с \"translate\" будут возвращать слова без перевода", envTranslateAPIEndpoint)
+	}
+
+	return &dictionaryTranslator{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		endpoint:   endpoint,
+	}
+}
+
+// dictionaryAPIResponse - форма ответа словарного API, которую мы понимаем
+type dictionaryAPIResponse struct {
+	Words []struct {
+		Phonetic string `json:"phonetic"`
+		PosList  []struct {
+			Pos          string `json:"pos"`
+			Explanations []struct {
+				Text string `json:"text"`
+			} `json:"explanations"`
+		} `json:"pos_list"`
+	} `json:"words"`
+}
+
+func (d *dictionaryTranslator) Translate(ctx context.Context, word string, targetLang string) (string, string, string, error) {
+	if d.endpoint == "" {
+		return "", "", "", fmt.Errorf("перевод отключен: не задана переменная окружения %s", envTranslateAPIEndpoint)
+	}
+
+	payload, err := json.Marshal(map[string]string{"word": word, "lang": targetLang})
+	if err != nil {
+		return "", "", "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", "", fmt.Errorf("словарное API вернуло статус %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var parsed dictionaryAPIResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", "", "", err
+	}
+	if len(parsed.Words) == 0 || len(parsed.Words[0].PosList) == 0 || len(parsed.Words[0].PosList[0].Explanations) == 0 {
+		return "", "", "", fmt.Errorf("в ответе словарного API нет перевода для %q", word)
+	}
+
+	entry := parsed.Words[0]
+	return entry.PosList[0].Explanations[0].Text, entry.PosList[0].Pos, entry.Phonetic, nil
+}
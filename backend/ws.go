@@ -0,0 +1,150 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultMaxWSDocumentRunes - лимит размера документа по умолчанию для /api/analyze/ws
+const defaultMaxWSDocumentRunes = 1 << 20 // ~1 МБ в рунах
+
+// wsDebounceInterval - через сколько после последнего кадра пересчитывается и отправляется анализ
+const wsDebounceInterval = 150 * time.Millisecond
+
+// wsMinFrameInterval - минимальный интервал между принимаемыми кадрами на одно соединение
+const wsMinFrameInterval = 20 * time.Millisecond
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsAnalysisMessage - сообщение с результатом анализа, отправляемое клиенту
+type wsAnalysisMessage struct {
+	CharCount     int              `json:"charCount"`
+	WordCount     int              `json:"wordCount"`
+	SentenceCount int              `json:"sentenceCount"`
+	ReadingTime   string           `json:"readingTime"`
+	FrequentWords []map[string]int `json:"frequentWords"`
+}
+
+// wsErrorMessage - сообщение об ошибке (превышен размер документа, слишком частые кадры и т.п.)
+type wsErrorMessage struct {
+	Error string `json:"error"`
+}
+
+// AnalyzeWSHandler апгрейдит соединение до websocket и на каждый полученный текстовый
+// кадр отдает инкрементально пересчитанный TextAnalysis с серверным дебаунсом
+// ~wsDebounceInterval, чтобы не пересчитывать анализ на каждое нажатие клавиши.
+func (h *HTTPHandler) AnalyzeWSHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("ошибка апгрейда websocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	out := &wsConn{conn: conn}
+
+	delimiter := r.URL.Query().Get("delimiter")
+	analyzer := NewIncrementalAnalyzer(delimiter, defaultMaxWSDocumentRunes)
+	limiter := newRateLimiter(wsMinFrameInterval)
+
+	var (
+		mu      sync.Mutex
+		pending string
+		timer   *time.Timer
+	)
+
+	flush := func() {
+		mu.Lock()
+		text := pending
+		mu.Unlock()
+
+		analysis, err := analyzer.Update(text)
+		if err != nil {
+			out.writeJSON(wsErrorMessage{Error: err.Error()})
+			return
+		}
+		out.writeJSON(wsAnalysisMessage{
+			CharCount:     analysis.CharCount,
+			WordCount:     analysis.WordCount,
+			SentenceCount: analysis.SentenceCount,
+			ReadingTime:   analysis.ReadingTime,
+			FrequentWords: convertToMap(analysis.FrequentWords),
+		})
+	}
+
+	for {
+		msgType, data, err := conn.ReadMessage()
+		if err != nil {
+			mu.Lock()
+			if timer != nil {
+				timer.Stop()
+			}
+			mu.Unlock()
+			return
+		}
+		if msgType != websocket.TextMessage {
+			continue
+		}
+
+		if !limiter.allow() {
+			out.writeJSON(wsErrorMessage{Error: "слишком много кадров, замедлитесь"})
+			continue
+		}
+
+		mu.Lock()
+		pending = string(data)
+		if timer == nil {
+			timer = time.AfterFunc(wsDebounceInterval, flush)
+		} else {
+			timer.Reset(wsDebounceInterval)
+		}
+		mu.Unlock()
+	}
+}
+
+// wsConn сериализует запись в *websocket.Conn: debounce-таймер (отдельная горутина)
+// и цикл чтения оба пишут в соединение, а gorilla/websocket не допускает
+// конкурентную запись без внешней синхронизации.
+type wsConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (c *wsConn) writeJSON(v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.conn.WriteJSON(v); err != nil {
+		log.Printf("ошибка записи в websocket: %v", err)
+	}
+}
+
+// rateLimiter - простое ограничение минимального интервала между событиями на соединение
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	return &rateLimiter{interval: interval}
+}
+
+func (rl *rateLimiter) allow() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if !rl.last.IsZero() && now.Sub(rl.last) < rl.interval {
+		return false
+	}
+	rl.last = now
+	return true
+}
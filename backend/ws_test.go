@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestAnalyzeWSHandler_IncrementalMatchesFullAnalysis открывает websocket, имитирует
+// серию нажатий клавиш и проверяет, что дельта после дебаунса совпадает с результатом
+// полного повторного анализа финального текста.
+func TestAnalyzeWSHandler_IncrementalMatchesFullAnalysis(t *testing.T) {
+	handler := NewHTTPHandler(NewDefaultAnalyzer())
+	server := httptest.NewServer(http.HandlerFunc(handler.AnalyzeWSHandler))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/analyze/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("не удалось подключиться к вебсокету: %v", err)
+	}
+	defer conn.Close()
+
+	keystrokes := []string{"Привет", "Привет мир", "Привет мир! Как дела?"}
+	for _, ks := range keystrokes {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(ks)); err != nil {
+			t.Fatalf("ошибка отправки кадра: %v", err)
+		}
+		// Держим кадры дальше друг от друга, чем wsMinFrameInterval, иначе
+		// лимитер частоты их отбросит и pending останется устаревшим
+		time.Sleep(wsMinFrameInterval + 10*time.Millisecond)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var got wsAnalysisMessage
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("не получен ответ после дебаунса: %v", err)
+		}
+
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(data, &probe); err != nil {
+			t.Fatalf("невалидный JSON в ответе: %v", err)
+		}
+		if _, isError := probe["error"]; isError {
+			continue // пропускаем случайный кадр об ошибке лимита частоты
+		}
+
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("невалидный JSON в ответе: %v", err)
+		}
+		break
+	}
+
+	finalText := keystrokes[len(keystrokes)-1]
+	want := NewDefaultAnalyzer().Analyze(context.Background(), finalText, "", ReadingSpeedOptions{}, nil, "")
+
+	if got.CharCount != want.CharCount {
+		t.Errorf("charCount = %d, want %d", got.CharCount, want.CharCount)
+	}
+	if got.WordCount != want.WordCount {
+		t.Errorf("wordCount = %d, want %d", got.WordCount, want.WordCount)
+	}
+	if got.SentenceCount != want.SentenceCount {
+		t.Errorf("sentenceCount = %d, want %d", got.SentenceCount, want.SentenceCount)
+	}
+	if got.ReadingTime != want.ReadingTime {
+		t.Errorf("readingTime = %q, want %q", got.ReadingTime, want.ReadingTime)
+	}
+}